@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/grafov/m3u8"
+)
+
+// appleMusicURLPattern extracts the storefront, entity kind and catalog id
+// out of a music.apple.com album/playlist/song URL, e.g.
+// https://music.apple.com/us/album/some-song/1234567890?i=1234567891
+// https://music.apple.com/us/playlist/some-playlist/pl.u-abc123
+var appleMusicURLPattern = regexp.MustCompile(`music\.apple\.com/([a-z]{2})/(album|playlist|song)/[^/]+/([\w.-]+)(?:\?i=(\d+))?`)
+
+// AppleMusicProvider fetches lossless ALAC or Dolby Atmos renditions of
+// Apple Music tracks, parallel to the yt-dlp path used for everything else.
+type AppleMusicProvider struct{}
+
+func (AppleMusicProvider) Name() string { return "apple-music" }
+
+func (AppleMusicProvider) CanHandle(track Track) bool {
+	return strings.Contains(track.URL, "music.apple.com")
+}
+
+func (p AppleMusicProvider) Extension(md *MusicDownloader) string {
+	// Both Atmos (EC-3) and ALAC renditions arrive as MPEG-4 segments, so
+	// both remux into an m4a container rather than flac, which ffmpeg's
+	// `-c copy` muxer rejects for non-FLAC codec streams.
+	return "m4a"
+}
+
+func (AppleMusicProvider) HandlesOwnMetadata() bool { return true }
+
+type appleMusicSongAttributes struct {
+	Name        string `json:"name"`
+	ArtistName  string `json:"artistName"`
+	AlbumName   string `json:"albumName"`
+	ReleaseDate string `json:"releaseDate"`
+	Artwork     struct {
+		URL string `json:"url"`
+	} `json:"artwork"`
+	ExtendedAssetUrls struct {
+		EnhancedHls string `json:"enhancedHls"`
+	} `json:"extendedAssetUrls"`
+}
+
+type appleMusicSongResponse struct {
+	Data []struct {
+		Attributes appleMusicSongAttributes `json:"attributes"`
+	} `json:"data"`
+}
+
+func (p AppleMusicProvider) fetchMetadata(md *MusicDownloader, storefront, songID string) (*appleMusicSongAttributes, error) {
+	endpoint := fmt.Sprintf("https://amp-api.music.apple.com/v1/catalog/%s/songs/%s?extend=extendedAssetUrls", storefront, songID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+md.config.AppleMusicAuthToken)
+	req.Header.Set("Media-User-Token", md.config.AppleMusicMediaUserToken)
+	req.Header.Set("Origin", "https://music.apple.com")
+
+	resp, err := md.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apple music catalog lookup failed: status %d", resp.StatusCode)
+	}
+
+	var parsed appleMusicSongResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Data) == 0 {
+		return nil, fmt.Errorf("song %s not found", songID)
+	}
+
+	return &parsed.Data[0].Attributes, nil
+}
+
+type appleMusicCollectionResponse struct {
+	Data []struct {
+		Relationships struct {
+			Tracks struct {
+				Data []struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			} `json:"tracks"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+// fetchCollectionTrackIDs resolves an album or playlist catalog id to the
+// ordered list of song ids it contains, so albums/playlists can be expanded
+// into their constituent tracks.
+func (p AppleMusicProvider) fetchCollectionTrackIDs(md *MusicDownloader, storefront, collection, id string) ([]string, error) {
+	endpoint := fmt.Sprintf("https://amp-api.music.apple.com/v1/catalog/%s/%s/%s?include=tracks", storefront, collection, id)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+md.config.AppleMusicAuthToken)
+	req.Header.Set("Media-User-Token", md.config.AppleMusicMediaUserToken)
+	req.Header.Set("Origin", "https://music.apple.com")
+
+	resp, err := md.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apple music %s lookup failed: status %d", collection, resp.StatusCode)
+	}
+
+	var parsed appleMusicCollectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Data) == 0 {
+		return nil, fmt.Errorf("%s %s not found", collection, id)
+	}
+
+	tracks := parsed.Data[0].Relationships.Tracks.Data
+	ids := make([]string, 0, len(tracks))
+	for _, t := range tracks {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// selectVariant picks the highest-bitrate playlist variant matching the
+// configured codec (ALAC or EC-3/Atmos), capped by MaxBitrate (kbps) when set.
+// If every matching variant exceeds MaxBitrate, the highest-bitrate one is
+// returned.
+func (p AppleMusicProvider) selectVariant(master *m3u8.MasterPlaylist, md *MusicDownloader) (*m3u8.Variant, error) {
+	wantAtmos := strings.EqualFold(md.config.AudioCodec, "atmos")
+
+	var candidates []*m3u8.Variant
+	for _, v := range master.Variants {
+		codecs := strings.ToLower(v.Codecs)
+		isAtmos := strings.Contains(codecs, "ec-3") || strings.Contains(codecs, "atmos")
+		isAlac := strings.Contains(codecs, "alac")
+
+		if wantAtmos && isAtmos {
+			candidates = append(candidates, v)
+		} else if !wantAtmos && isAlac {
+			candidates = append(candidates, v)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no matching %s rendition available", md.config.AudioCodec)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Bandwidth > candidates[j].Bandwidth
+	})
+
+	if md.config.MaxBitrate > 0 {
+		for _, v := range candidates {
+			if int(v.Bandwidth)/1000 <= md.config.MaxBitrate {
+				return v, nil
+			}
+		}
+	}
+
+	return candidates[0], nil
+}
+
+func (p AppleMusicProvider) Download(md *MusicDownloader, track Track, outputPath string, onProgress ProgressFunc) error {
+	report := func(phase string, percent float64) {
+		if onProgress != nil {
+			onProgress(phase, percent)
+		}
+	}
+
+	matches := appleMusicURLPattern.FindStringSubmatch(track.URL)
+	if matches == nil {
+		return fmt.Errorf("could not parse apple music URL: %s", track.URL)
+	}
+	storefront, kind, id, songQuery := matches[1], matches[2], matches[3], matches[4]
+
+	var songIDs []string
+	switch kind {
+	case "song":
+		songIDs = []string{id}
+	case "album":
+		if songQuery != "" {
+			songIDs = []string{songQuery}
+		} else {
+			ids, err := p.fetchCollectionTrackIDs(md, storefront, "albums", id)
+			if err != nil {
+				return err
+			}
+			songIDs = ids
+		}
+	case "playlist":
+		ids, err := p.fetchCollectionTrackIDs(md, storefront, "playlists", id)
+		if err != nil {
+			return err
+		}
+		songIDs = ids
+	}
+	if len(songIDs) == 0 {
+		return fmt.Errorf("no tracks found for %s", track.URL)
+	}
+
+	if len(songIDs) == 1 {
+		return p.downloadSong(md, storefront, songIDs[0], outputPath, report)
+	}
+
+	dir := filepath.Dir(outputPath)
+	var firstErr error
+	for i, songID := range songIDs {
+		report(fmt.Sprintf("track %d/%d", i+1, len(songIDs)), float64(i)/float64(len(songIDs))*100)
+		attrs, err := p.fetchMetadata(md, storefront, songID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		name := fmt.Sprintf("%s%s%s", attrs.Name, md.config.MusicSeparator, attrs.ArtistName)
+		trackOutputPath := filepath.Join(dir, name)
+		if err := p.downloadSongAttrs(md, attrs, trackOutputPath, report); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	report("done", 100)
+	return firstErr
+}
+
+// downloadSong fetches catalog metadata for a single song id and downloads it.
+func (p AppleMusicProvider) downloadSong(md *MusicDownloader, storefront, songID, outputPath string, report func(phase string, percent float64)) error {
+	report("fetching metadata", 0)
+	attrs, err := p.fetchMetadata(md, storefront, songID)
+	if err != nil {
+		return err
+	}
+	return p.downloadSongAttrs(md, attrs, outputPath, report)
+}
+
+// downloadSongAttrs downloads, remuxes and tags a single song whose catalog
+// metadata has already been resolved.
+func (p AppleMusicProvider) downloadSongAttrs(md *MusicDownloader, attrs *appleMusicSongAttributes, outputPath string, report func(phase string, percent float64)) error {
+	if attrs.ExtendedAssetUrls.EnhancedHls == "" {
+		return fmt.Errorf("no HLS asset available for %s", attrs.Name)
+	}
+
+	resp, err := md.client.Get(attrs.ExtendedAssetUrls.EnhancedHls)
+	if err != nil {
+		return fmt.Errorf("failed to fetch master playlist: %v", err)
+	}
+	defer resp.Body.Close()
+
+	playlist, listType, err := m3u8.DecodeFrom(resp.Body, true)
+	if err != nil {
+		return fmt.Errorf("failed to parse master playlist: %v", err)
+	}
+	if listType != m3u8.MASTER {
+		return fmt.Errorf("expected a master playlist, got a media playlist")
+	}
+	master := playlist.(*m3u8.MasterPlaylist)
+
+	variant, err := p.selectVariant(master, md)
+	if err != nil {
+		return err
+	}
+
+	mediaResp, err := md.client.Get(variant.URI)
+	if err != nil {
+		return fmt.Errorf("failed to fetch media playlist: %v", err)
+	}
+	defer mediaResp.Body.Close()
+
+	mediaPlaylist, mediaListType, err := m3u8.DecodeFrom(mediaResp.Body, true)
+	if err != nil {
+		return fmt.Errorf("failed to parse media playlist: %v", err)
+	}
+	if mediaListType != m3u8.MEDIA {
+		return fmt.Errorf("expected a media playlist")
+	}
+	media := mediaPlaylist.(*m3u8.MediaPlaylist)
+
+	segmentPath := outputPath + ".segments.m4s"
+	if err := p.downloadSegments(md, media, segmentPath, report); err != nil {
+		return err
+	}
+	defer os.Remove(segmentPath)
+
+	report("remuxing", 90)
+	finalPath := outputPath + "." + p.Extension(md)
+	if err := p.remux(segmentPath, finalPath); err != nil {
+		return err
+	}
+
+	report("tagging", 95)
+
+	coverURL := strings.NewReplacer("{w}", "1400", "{h}", "1400").Replace(attrs.Artwork.URL)
+	metadata := &TrackMetadata{
+		Title:    attrs.Name,
+		Artist:   attrs.ArtistName,
+		Album:    attrs.AlbumName,
+		Year:     attrs.ReleaseDate,
+		CoverURL: coverURL,
+	}
+	md.addMetadataToFile(finalPath, metadata)
+	report("done", 100)
+
+	return nil
+}
+
+// fetchInitSegment retrieves the EXT-X-MAP initialization segment (the
+// ftyp/moov boxes Apple ships separately from the bare moof+mdat media
+// fragments) that must precede the first matching media segment in the
+// output file.
+func (p AppleMusicProvider) fetchInitSegment(md *MusicDownloader, m *m3u8.Map) ([]byte, error) {
+	req, err := http.NewRequest("GET", m.URI, nil)
+	if err != nil {
+		return nil, err
+	}
+	if m.Limit > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", m.Offset, m.Offset+m.Limit-1))
+	}
+
+	resp, err := md.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("failed to fetch init segment: status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// downloadSegments fetches every .m4s segment referenced by the media
+// playlist concurrently and concatenates them, in order, into destPath.
+// Fragmented MP4 streams are designed to be valid when segments are
+// byte-concatenated this way, but each run of segments must be preceded by
+// its EXT-X-MAP initialization segment, which carries the ftyp/moov boxes
+// the bare fragments don't repeat.
+func (p AppleMusicProvider) downloadSegments(md *MusicDownloader, media *m3u8.MediaPlaylist, destPath string, report func(phase string, percent float64)) error {
+	var segments []*m3u8.MediaSegment
+	for _, seg := range media.Segments {
+		if seg != nil {
+			segments = append(segments, seg)
+		}
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("media playlist has no segments")
+	}
+
+	var fetched int32
+	total := len(segments)
+
+	buffers := make([][]byte, len(segments))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	semaphore := make(chan struct{}, md.config.MaxConcurrent)
+
+	for i, seg := range segments {
+		wg.Add(1)
+		go func(i int, uri string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			resp, err := md.client.Get(uri)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			buffers[i] = data
+			done := atomic.AddInt32(&fetched, 1)
+			report("downloading", float64(done)/float64(total)*90)
+		}(i, seg.URI)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("failed to fetch segment: %v", firstErr)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	initSegments := make(map[string][]byte)
+	var lastMapURI string
+	for i, seg := range segments {
+		segMap := seg.Map
+		if segMap == nil {
+			segMap = media.Map
+		}
+		if segMap != nil && segMap.URI != lastMapURI {
+			data, ok := initSegments[segMap.URI]
+			if !ok {
+				data, err = p.fetchInitSegment(md, segMap)
+				if err != nil {
+					return fmt.Errorf("failed to fetch init segment: %v", err)
+				}
+				initSegments[segMap.URI] = data
+			}
+			if _, err := file.Write(data); err != nil {
+				return err
+			}
+			lastMapURI = segMap.URI
+		}
+
+		if _, err := file.Write(buffers[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p AppleMusicProvider) remux(segmentPath, finalPath string) error {
+	cmd := exec.Command("ffmpeg",
+		"-i", segmentPath,
+		"-c", "copy",
+		"-y", finalPath,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg remux failed: %v - %s", err, stderr.String())
+	}
+
+	return nil
+}
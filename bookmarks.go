@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v3"
+	"howett.net/plist"
+)
+
+// BookmarkSource produces the list of tracks to download from whatever
+// input format the user has configured (Chrome JSON, Firefox's places.sqlite,
+// Safari's Bookmarks.plist, plain URL lists, M3U playlists, an Obsidian
+// YAML/Markdown note, or the user's Spotify library).
+type BookmarkSource interface {
+	ParseTracks(md *MusicDownloader) ([]Track, error)
+}
+
+// newBookmarkSource resolves the configured source_type to its implementation.
+func newBookmarkSource(sourceType string) (BookmarkSource, error) {
+	switch strings.ToLower(sourceType) {
+	case "", "chrome":
+		return ChromeBookmarkSource{}, nil
+	case "firefox":
+		return FirefoxBookmarkSource{}, nil
+	case "safari":
+		return SafariBookmarkSource{}, nil
+	case "textfile":
+		return TextListBookmarkSource{}, nil
+	case "m3u", "m3u8":
+		return M3UBookmarkSource{}, nil
+	case "obsidian":
+		return ObsidianBookmarkSource{}, nil
+	case "spotify":
+		return SpotifyLibraryBookmarkSource{}, nil
+	default:
+		return nil, fmt.Errorf("unknown source_type: %s", sourceType)
+	}
+}
+
+// ChromeBookmarkSource reads Chrome's (or Chromium-based browsers') JSON
+// Bookmarks file. This is the original, still-default behavior.
+type ChromeBookmarkSource struct{}
+
+func (ChromeBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	data, err := os.ReadFile(md.config.BookmarkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %v", err)
+	}
+
+	var bookmarks Bookmarks
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks: %v", err)
+	}
+
+	if md.config.BookmarkPosition >= len(bookmarks.Roots.BookmarkBar.Children) {
+		return nil, fmt.Errorf("bookmark position %d out of range", md.config.BookmarkPosition)
+	}
+
+	musicFolder := bookmarks.Roots.BookmarkBar.Children[md.config.BookmarkPosition]
+	var tracks []Track
+
+	for _, bookmark := range musicFolder.Children {
+		if bookmark.Type == "url" {
+			tracks = append(tracks, md.parseTrackName(bookmark.Name, bookmark.URL))
+		}
+	}
+
+	return tracks, nil
+}
+
+// FirefoxBookmarkSource reads bookmarks straight out of Firefox's
+// places.sqlite, joining moz_bookmarks against moz_places for the URL.
+type FirefoxBookmarkSource struct{}
+
+func (FirefoxBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	path := md.config.FirefoxProfilePath
+	if path == "" {
+		return nil, fmt.Errorf("firefox_profile_path not configured")
+	}
+	// Accept either the profile directory or its places.sqlite file.
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "places.sqlite")
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&immutable=1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open places.sqlite: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT b.title, p.url
+		FROM moz_bookmarks b
+		JOIN moz_places p ON b.fk = p.id
+		WHERE b.type = 1 AND p.url IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %v", err)
+	}
+	defer rows.Close()
+
+	var tracks []Track
+	for rows.Next() {
+		var title, url string
+		if err := rows.Scan(&title, &url); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark row: %v", err)
+		}
+		if title == "" {
+			continue
+		}
+		tracks = append(tracks, md.parseTrackName(title, url))
+	}
+
+	return tracks, rows.Err()
+}
+
+// SafariBookmarkSource reads macOS Safari's binary/XML Bookmarks.plist.
+type SafariBookmarkSource struct{}
+
+type safariPlistNode struct {
+	WebBookmarkType string            `plist:"WebBookmarkType"`
+	URLString       string            `plist:"URLString"`
+	URIDictionary   map[string]string `plist:"URIDictionary"`
+	Children        []safariPlistNode `plist:"Children"`
+}
+
+func (SafariBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	path := md.config.SafariBookmarksPath
+	if path == "" {
+		return nil, fmt.Errorf("safari_bookmarks_path not configured")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Safari bookmarks: %v", err)
+	}
+
+	var root safariPlistNode
+	if _, err := plist.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse Safari bookmarks: %v", err)
+	}
+
+	var tracks []Track
+	var walk func(node safariPlistNode)
+	walk = func(node safariPlistNode) {
+		if node.WebBookmarkType == "WebBookmarkTypeLeaf" && node.URLString != "" {
+			title := node.URIDictionary["title"]
+			if title == "" {
+				title = node.URLString
+			}
+			tracks = append(tracks, md.parseTrackName(title, node.URLString))
+			return
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return tracks, nil
+}
+
+// TextListBookmarkSource reads a plain-text file with one URL per line.
+// The track name is derived from the URL itself since no title is available.
+type TextListBookmarkSource struct{}
+
+func (TextListBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	path := md.config.TextListPath
+	if path == "" {
+		return nil, fmt.Errorf("text_list_path not configured")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open text list: %v", err)
+	}
+	defer file.Close()
+
+	var tracks []Track
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tracks = append(tracks, md.parseTrackName(line, line))
+	}
+
+	return tracks, scanner.Err()
+}
+
+// M3UBookmarkSource reads an M3U/M3U8 playlist, using the #EXTINF "artist -
+// title" metadata line when present and falling back to the bare URL.
+type M3UBookmarkSource struct{}
+
+func (M3UBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	path := md.config.M3UPath
+	if path == "" {
+		return nil, fmt.Errorf("m3u_path not configured")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open M3U playlist: %v", err)
+	}
+	defer file.Close()
+
+	var tracks []Track
+	var pendingName string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#EXTINF:") {
+			// #EXTINF:<duration>,<artist - title>
+			if idx := strings.Index(line, ","); idx != -1 {
+				pendingName = strings.TrimSpace(line[idx+1:])
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name := pendingName
+		if name == "" {
+			name = line
+		}
+		tracks = append(tracks, md.parseTrackName(name, line))
+		pendingName = ""
+	}
+
+	return tracks, scanner.Err()
+}
+
+// ObsidianBookmarkSource reads an Obsidian-style Markdown note whose body is
+// a YAML list of entries, e.g.:
+//
+//   - title: Some Song
+//     artist: Some Artist
+//     url: https://youtube.com/watch?v=...
+type ObsidianBookmarkSource struct{}
+
+type obsidianEntry struct {
+	Title  string `yaml:"title"`
+	Artist string `yaml:"artist"`
+	URL    string `yaml:"url"`
+}
+
+func (ObsidianBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	path := md.config.ObsidianFilePath
+	if path == "" {
+		return nil, fmt.Errorf("obsidian_file_path not configured")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Obsidian file: %v", err)
+	}
+
+	body := string(data)
+	if strings.HasPrefix(body, "---") {
+		if end := strings.Index(body[3:], "---"); end != -1 {
+			body = body[3+end+3:]
+		}
+	}
+
+	var entries []obsidianEntry
+	if err := yaml.Unmarshal([]byte(body), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse Obsidian entries: %v", err)
+	}
+
+	var tracks []Track
+	for _, entry := range entries {
+		if entry.URL == "" {
+			continue
+		}
+		title := strings.TrimSpace(entry.Title)
+		artist := strings.TrimSpace(entry.Artist)
+		if title == "" {
+			title = entry.URL
+		}
+		if artist == "" {
+			artist = "Unknown Artist"
+		}
+		tracks = append(tracks, Track{
+			URL:    entry.URL,
+			Name:   fmt.Sprintf("%s%s%s", title, md.config.MusicSeparator, artist),
+			Title:  title,
+			Artist: artist,
+		})
+	}
+
+	return tracks, nil
+}
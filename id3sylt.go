@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// embedSyltFrame inserts an ID3v2.3 SYLT (synchronized lyrics) frame into an
+// mp3 file that already carries an ID3v2 tag (written by ffmpeg's
+// -id3v2_version 3 pass in addMetadataToFile), growing the tag as needed.
+func embedSyltFrame(path string, lines []LyricLine) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return fmt.Errorf("file has no ID3v2 tag to extend")
+	}
+
+	tagSize := syncsafeDecode(data[6:10])
+	header := data[:10]
+	tagBody := data[10 : 10+tagSize]
+	audio := data[10+tagSize:]
+
+	frame := buildSyltFrame(lines)
+
+	newTagSize := tagSize + uint32(len(frame))
+	newHeader := make([]byte, 10)
+	copy(newHeader, header)
+	sizeBytes := syncsafeEncode(newTagSize)
+	copy(newHeader[6:10], sizeBytes[:])
+
+	out := make([]byte, 0, len(newHeader)+len(tagBody)+len(frame)+len(audio))
+	out = append(out, newHeader...)
+	out = append(out, tagBody...)
+	out = append(out, frame...)
+	out = append(out, audio...)
+
+	return os.WriteFile(path, out, 0644)
+}
+
+// buildSyltFrame encodes lines as an ID3v2.3 SYLT frame: ISO-8859-1 text
+// encoding, English language, millisecond timestamps, lyrics content type.
+func buildSyltFrame(lines []LyricLine) []byte {
+	var body []byte
+	body = append(body, 0x00)          // text encoding: ISO-8859-1
+	body = append(body, 'e', 'n', 'g') // language
+	body = append(body, 0x02)          // timestamp format: milliseconds
+	body = append(body, 0x01)          // content type: lyrics
+	body = append(body, 0x00)          // content descriptor (empty, terminated)
+
+	for _, line := range lines {
+		body = append(body, []byte(line.Text)...)
+		body = append(body, 0x00) // string terminator
+
+		var ts [4]byte
+		binary.BigEndian.PutUint32(ts[:], uint32(line.Timestamp.Milliseconds()))
+		body = append(body, ts[:]...)
+	}
+
+	frame := make([]byte, 0, 10+len(body))
+	frame = append(frame, 'S', 'Y', 'L', 'T')
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(body)))
+	frame = append(frame, size[:]...)
+
+	frame = append(frame, 0x00, 0x00) // frame flags
+	frame = append(frame, body...)
+
+	return frame
+}
+
+func syncsafeEncode(n uint32) [4]byte {
+	var b [4]byte
+	b[0] = byte((n >> 21) & 0x7F)
+	b[1] = byte((n >> 14) & 0x7F)
+	b[2] = byte((n >> 7) & 0x7F)
+	b[3] = byte(n & 0x7F)
+	return b
+}
+
+func syncsafeDecode(b []byte) uint32 {
+	return uint32(b[0])<<21 | uint32(b[1])<<14 | uint32(b[2])<<7 | uint32(b[3])
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// lrcLinePattern matches one LRC line: "[mm:ss.xx]lyric text".
+var lrcLinePattern = regexp.MustCompile(`^\[(\d+):(\d+(?:\.\d+)?)\](.*)$`)
+
+// LyricLine is a single timestamped line of synchronized lyrics.
+type LyricLine struct {
+	Timestamp time.Duration
+	Text      string
+}
+
+// fetchLyrics populates metadata.Lyrics and metadata.SyncedLyrics, first
+// trying Spotify's lyrics endpoint (using the track ID resolved by
+// getSpotifyMetadata) and falling back to lrclib.net when Spotify has none.
+func (md *MusicDownloader) fetchLyrics(track Track, metadata *TrackMetadata) error {
+	if metadata.SpotifyTrackID != "" {
+		if plain, synced, err := md.fetchSpotifyLyrics(metadata.SpotifyTrackID); err == nil {
+			metadata.Lyrics = plain
+			metadata.SyncedLyrics = synced
+			return nil
+		}
+	}
+
+	plain, synced, err := fetchLrclibLyrics(track.Title, track.Artist)
+	if err != nil {
+		return err
+	}
+
+	metadata.Lyrics = plain
+	metadata.SyncedLyrics = synced
+
+	return nil
+}
+
+func (md *MusicDownloader) fetchSpotifyLyrics(spotifyTrackID string) (plain, synced string, err error) {
+	if md.spotifyToken == "" {
+		if err := md.getSpotifyToken(); err != nil {
+			return "", "", err
+		}
+	}
+
+	endpoint := fmt.Sprintf("https://api.spotify.com/v1/tracks/%s/lyrics", spotifyTrackID)
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+md.spotifyToken)
+
+	resp, err := md.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("spotify lyrics endpoint returned status %d", resp.StatusCode)
+	}
+
+	var lyricsResp struct {
+		Lyrics struct {
+			Lines []struct {
+				StartTimeMs string `json:"startTimeMs"`
+				Words       string `json:"words"`
+			} `json:"lines"`
+		} `json:"lyrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&lyricsResp); err != nil {
+		return "", "", err
+	}
+	if len(lyricsResp.Lyrics.Lines) == 0 {
+		return "", "", fmt.Errorf("no lyrics available")
+	}
+
+	var plainBuilder, lrcBuilder strings.Builder
+	for _, line := range lyricsResp.Lyrics.Lines {
+		plainBuilder.WriteString(line.Words)
+		plainBuilder.WriteString("\n")
+
+		ms, _ := strconv.Atoi(line.StartTimeMs)
+		lrcBuilder.WriteString(formatLRCTimestamp(time.Duration(ms) * time.Millisecond))
+		lrcBuilder.WriteString(line.Words)
+		lrcBuilder.WriteString("\n")
+	}
+
+	return plainBuilder.String(), lrcBuilder.String(), nil
+}
+
+// fetchLrclibLyrics queries the public lrclib.net API, which requires no
+// authentication and indexes lyrics by plain title/artist.
+func fetchLrclibLyrics(title, artist string) (plain, synced string, err error) {
+	endpoint := fmt.Sprintf("https://lrclib.net/api/get?track_name=%s&artist_name=%s",
+		url.QueryEscape(title), url.QueryEscape(artist))
+
+	resp, err := http.Get(endpoint)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("lrclib lookup failed: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PlainLyrics  string `json:"plainLyrics"`
+		SyncedLyrics string `json:"syncedLyrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", err
+	}
+	if result.PlainLyrics == "" && result.SyncedLyrics == "" {
+		return "", "", fmt.Errorf("no lyrics found on lrclib")
+	}
+
+	return result.PlainLyrics, result.SyncedLyrics, nil
+}
+
+func formatLRCTimestamp(d time.Duration) string {
+	minutes := int(d.Minutes())
+	seconds := d.Seconds() - float64(minutes)*60
+	return fmt.Sprintf("[%02d:%05.2f]", minutes, seconds)
+}
+
+// parseLRC turns an LRC-formatted string into ordered timestamped lines,
+// skipping metadata tags like "[ar:...]" that carry no timestamp.
+func parseLRC(lrc string) ([]LyricLine, error) {
+	var lines []LyricLine
+
+	for _, raw := range strings.Split(lrc, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		match := lrcLinePattern.FindStringSubmatch(raw)
+		if match == nil {
+			continue
+		}
+
+		minutes, _ := strconv.Atoi(match[1])
+		seconds, _ := strconv.ParseFloat(match[2], 64)
+		timestamp := time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+
+		lines = append(lines, LyricLine{Timestamp: timestamp, Text: match[3]})
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no timestamped lines found")
+	}
+
+	return lines, nil
+}
+
+// writeLyricsOutputs writes a sibling lyrics file next to the downloaded
+// track when SaveLrcFile is enabled, in the format selected by
+// config.LrcFormat ("lrc" or "ttml").
+func (md *MusicDownloader) writeLyricsOutputs(outputPath string, metadata *TrackMetadata) {
+	if !md.config.SaveLrcFile || metadata.SyncedLyrics == "" {
+		return
+	}
+
+	basePath := strings.TrimSuffix(outputPath, path.Ext(outputPath))
+
+	if strings.EqualFold(md.config.LrcFormat, "ttml") {
+		lines, err := parseLRC(metadata.SyncedLyrics)
+		if err != nil {
+			color.Yellow("⚠️  Could not convert synced lyrics to TTML for %s: %v", metadata.Title, err)
+			return
+		}
+		if err := os.WriteFile(basePath+".ttml", []byte(formatTTML(lines)), 0644); err != nil {
+			color.Yellow("⚠️  Could not write .ttml file for %s: %v", metadata.Title, err)
+		}
+		return
+	}
+
+	if err := os.WriteFile(basePath+".lrc", []byte(metadata.SyncedLyrics), 0644); err != nil {
+		color.Yellow("⚠️  Could not write .lrc file for %s: %v", metadata.Title, err)
+	}
+}
+
+// formatTTML renders timestamped lyric lines as a minimal TTML document,
+// one <p> per line with begin/end times in TTML clock-time format.
+func formatTTML(lines []LyricLine) string {
+	var body strings.Builder
+	body.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	body.WriteString("<tt xmlns=\"http://www.w3.org/ns/ttml\">\n  <body>\n    <div>\n")
+
+	for i, line := range lines {
+		end := line.Timestamp
+		if i+1 < len(lines) {
+			end = lines[i+1].Timestamp
+		}
+		fmt.Fprintf(&body, "      <p begin=\"%s\" end=\"%s\">%s</p>\n",
+			formatTTMLTimestamp(line.Timestamp), formatTTMLTimestamp(end), escapeXML(line.Text))
+	}
+
+	body.WriteString("    </div>\n  </body>\n</tt>\n")
+	return body.String()
+}
+
+func formatTTMLTimestamp(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := d.Seconds() - float64(hours)*3600 - float64(minutes)*60
+	return fmt.Sprintf("%02d:%02d:%06.3f", hours, minutes, seconds)
+}
+
+func escapeXML(s string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(s)
+}
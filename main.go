@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 )
@@ -35,6 +37,36 @@ type Config struct {
 	YtDlpPath           string `json:"yt_dlp_path"`
 	MaxConcurrent       int    `json:"max_concurrent"`
 	AudioQuality        string `json:"audio_quality"`
+
+	// SourceType selects the BookmarkSource implementation: "chrome" (default),
+	// "firefox", "safari", "textfile", "m3u"/"m3u8", "obsidian", or "spotify".
+	SourceType          string `json:"source_type"`
+	FirefoxProfilePath  string `json:"firefox_profile_path"`
+	SafariBookmarksPath string `json:"safari_bookmarks_path"`
+	TextListPath        string `json:"text_list_path"`
+	M3UPath             string `json:"m3u_path"`
+	ObsidianFilePath    string `json:"obsidian_file_path"`
+
+	// Spotify OAuth PKCE options, used when SourceType is "spotify".
+	SpotifyPlaylistID   string `json:"spotify_playlist_id"`
+	SpotifyRedirectPort string `json:"spotify_redirect_port"`
+	SpotifyTokenPath    string `json:"spotify_token_path"`
+
+	// Apple Music provider options, used for music.apple.com track URLs.
+	AppleMusicMediaUserToken string `json:"apple_music_media_user_token"`
+	AppleMusicAuthToken      string `json:"apple_music_auth_token"`
+	AudioCodec               string `json:"audio_codec"` // "alac" or "atmos"
+	MaxBitrate               int    `json:"max_bitrate"`
+
+	// Lyrics options.
+	SaveLrcFile bool   `json:"save_lrc_file"`
+	EmbedLrc    bool   `json:"embed_lrc"`
+	LrcFormat   string `json:"lrc_format"` // "lrc" or "ttml"
+
+	// Resumable download queue options.
+	DownloadDBPath      string `json:"download_db_path"`
+	MaxRetries          int    `json:"max_retries"`
+	RetryBackoffSeconds int    `json:"retry_backoff_seconds"`
 }
 
 func defaultConfig() Config {
@@ -63,6 +95,11 @@ func defaultConfig() Config {
 		YtDlpPath:           "yt-dlp", // Will be auto-downloaded
 		MaxConcurrent:       3,
 		AudioQuality:        "192k",
+		SourceType:          "chrome",
+		LrcFormat:           "lrc",
+		DownloadDBPath:      "downloads.db",
+		MaxRetries:          3,
+		RetryBackoffSeconds: 5,
 	}
 }
 
@@ -76,7 +113,11 @@ type Track struct {
 type SpotifySearchResponse struct {
 	Tracks struct {
 		Items []struct {
-			Name    string `json:"name"`
+			ID          string `json:"id"`
+			Name        string `json:"name"`
+			ExternalIDs struct {
+				ISRC string `json:"isrc"`
+			} `json:"external_ids"`
 			Artists []struct {
 				Name string `json:"name"`
 			} `json:"artists"`
@@ -98,11 +139,15 @@ type SpotifyTokenResponse struct {
 }
 
 type TrackMetadata struct {
-	Title    string
-	Artist   string
-	Album    string
-	Year     string
-	CoverURL string
+	Title          string
+	Artist         string
+	Album          string
+	Year           string
+	CoverURL       string
+	Lyrics         string // plain, unsynchronized lyrics
+	SyncedLyrics   string // LRC-formatted, "[mm:ss.xx]text" per line
+	SpotifyTrackID string
+	ISRC           string
 }
 
 type BookmarkNode struct {
@@ -209,34 +254,6 @@ func (md *MusicDownloader) setupFFmpeg() error {
 	return nil
 }
 
-func (md *MusicDownloader) parseBookmarks() ([]Track, error) {
-	data, err := os.ReadFile(md.config.BookmarkPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read bookmarks: %v", err)
-	}
-
-	var bookmarks Bookmarks
-	if err := json.Unmarshal(data, &bookmarks); err != nil {
-		return nil, fmt.Errorf("failed to parse bookmarks: %v", err)
-	}
-
-	if md.config.BookmarkPosition >= len(bookmarks.Roots.BookmarkBar.Children) {
-		return nil, fmt.Errorf("bookmark position %d out of range", md.config.BookmarkPosition)
-	}
-
-	musicFolder := bookmarks.Roots.BookmarkBar.Children[md.config.BookmarkPosition]
-	var tracks []Track
-
-	for _, bookmark := range musicFolder.Children {
-		if bookmark.Type == "url" {
-			track := md.parseTrackName(bookmark.Name, bookmark.URL)
-			tracks = append(tracks, track)
-		}
-	}
-
-	return tracks, nil
-}
-
 func (md *MusicDownloader) parseTrackName(name, url string) Track {
 	parts := strings.Split(name, md.config.MusicSeparator)
 
@@ -326,10 +343,12 @@ func (md *MusicDownloader) getSpotifyMetadata(track Track) (*TrackMetadata, erro
 
 	item := searchResp.Tracks.Items[0]
 	metadata := &TrackMetadata{
-		Title:  item.Name,
-		Artist: item.Artists[0].Name,
-		Album:  item.Album.Name,
-		Year:   item.Album.ReleaseDate[:4],
+		Title:          item.Name,
+		Artist:         item.Artists[0].Name,
+		Album:          item.Album.Name,
+		Year:           item.Album.ReleaseDate[:4],
+		SpotifyTrackID: item.ID,
+		ISRC:           item.ExternalIDs.ISRC,
 	}
 
 	if len(item.Album.Images) > 0 {
@@ -365,43 +384,57 @@ func (md *MusicDownloader) downloadCoverImage(coverURL, tempPath string) error {
 	return err
 }
 
-func (md *MusicDownloader) downloadTrack(track Track, bar *progressbar.ProgressBar) error {
-	outputPath := filepath.Join(md.config.MusicDirectory, track.Name+".mp3")
+// downloadTrack downloads a single track and returns the final file path
+// and, when Spotify metadata was resolved, its ISRC (used by ProcessTracks
+// to deduplicate the same song reached via different URLs). report is
+// called with the current phase and percent complete as work progresses.
+func (md *MusicDownloader) downloadTrack(track Track, report ProgressFunc) (string, string, error) {
+	provider, err := providerFor(track)
+	if err != nil {
+		return "", "", err
+	}
+
+	basePath := filepath.Join(md.config.MusicDirectory, track.Name)
+	outputPath := basePath + "." + provider.Extension(md)
 
 	if _, err := os.Stat(outputPath); err == nil {
-		bar.Describe(fmt.Sprintf("⏭️  Skipping existing: %s", track.Name))
-		return nil
+		report("skipped (exists)", 100)
+		return outputPath, "", nil
 	}
 
-	bar.Describe(fmt.Sprintf("📥 Downloading: %s", track.Name))
+	report("downloading", 0)
 
-	cmd := exec.Command(md.config.YtDlpPath,
-		"--extract-audio",
-		"--audio-format", "mp3",
-		"--audio-quality", md.config.AudioQuality,
-		"--output", filepath.Join(md.config.MusicDirectory, track.Name+".%(ext)s"),
-		"--quiet",
-		"--no-warnings",
-		track.URL,
-	)
+	if err := provider.Download(md, track, basePath, report); err != nil {
+		return "", "", err
+	}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	if provider.HandlesOwnMetadata() {
+		report("done", 100)
+		return outputPath, "", nil
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("download failed: %v - %s", err, stderr.String())
+	if md.config.SpotifyClientID == "" {
+		emitLog("⚠️  Skipping metadata: Spotify credentials not configured")
+		report("done", 100)
+		return outputPath, "", nil
 	}
 
-	if md.config.SpotifyClientID != "" {
-		bar.Describe(fmt.Sprintf("🎵 Adding metadata: %s", track.Name))
-		if metadata, err := md.getSpotifyMetadata(track); err == nil {
-			md.addMetadataToFile(outputPath, metadata)
+	report("tagging", 90)
+	metadata, err := md.getSpotifyMetadata(track)
+	if err != nil {
+		report("done", 100)
+		return outputPath, "", nil
+	}
+
+	if md.config.SaveLrcFile || md.config.EmbedLrc {
+		if err := md.fetchLyrics(track, metadata); err != nil {
+			color.Yellow("⚠️  Could not fetch lyrics for %s: %v", track.Name, err)
 		}
-	} else {
-		color.Yellow("⚠️  Skipping metadata: Spotify credentials not configured")
 	}
+	md.addMetadataToFile(outputPath, metadata)
+	md.writeLyricsOutputs(outputPath, metadata)
 
-	return nil
+	return outputPath, metadata.ISRC, nil
 }
 
 func (md *MusicDownloader) addMetadataToFile(filepath string, metadata *TrackMetadata) error {
@@ -424,45 +457,36 @@ func (md *MusicDownloader) addMetadataToFile(filepath string, metadata *TrackMet
 		}
 	}()
 
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filepath), "."))
+	if ext == "" {
+		ext = "mp3"
+	}
 	outputPath := filepath + ".tmp"
 
-	var args []string
-
+	args := []string{"-i", filepath}
 	if coverPath != "" {
-		args = []string{
-			"-i", filepath,
-			"-i", coverPath,
-			"-map", "0:a",
-			"-map", "1:0",
-			"-c:a", "copy",
-			"-c:v", "mjpeg",
-			"-disposition:v:0", "attached_pic",
-			"-metadata", fmt.Sprintf("title=%s", metadata.Title),
-			"-metadata", fmt.Sprintf("artist=%s", metadata.Artist),
-			"-metadata", fmt.Sprintf("album=%s", metadata.Album),
-			"-metadata", fmt.Sprintf("date=%s", metadata.Year),
-			"-id3v2_version", "3",
-			"-write_id3v1", "1",
-			"-f", "mp3",
-			"-y",
-			outputPath,
-		}
+		args = append(args, "-i", coverPath, "-map", "0:a", "-map", "1:0", "-c:a", "copy", "-c:v", "mjpeg", "-disposition:v:0", "attached_pic")
 	} else {
-		args = []string{
-			"-i", filepath,
-			"-c", "copy",
-			"-metadata", fmt.Sprintf("title=%s", metadata.Title),
-			"-metadata", fmt.Sprintf("artist=%s", metadata.Artist),
-			"-metadata", fmt.Sprintf("album=%s", metadata.Album),
-			"-metadata", fmt.Sprintf("date=%s", metadata.Year),
-			"-id3v2_version", "3",
-			"-write_id3v1", "1",
-			"-f", "mp3",
-			"-y",
-			outputPath,
-		}
+		args = append(args, "-c", "copy")
+	}
+
+	args = append(args,
+		"-metadata", fmt.Sprintf("title=%s", metadata.Title),
+		"-metadata", fmt.Sprintf("artist=%s", metadata.Artist),
+		"-metadata", fmt.Sprintf("album=%s", metadata.Album),
+		"-metadata", fmt.Sprintf("date=%s", metadata.Year),
+	)
+
+	if md.config.EmbedLrc && metadata.Lyrics != "" {
+		args = append(args, "-metadata", fmt.Sprintf("lyrics-eng=%s", metadata.Lyrics))
+	}
+
+	if ext == "mp3" {
+		args = append(args, "-id3v2_version", "3", "-write_id3v1", "1")
 	}
 
+	args = append(args, "-f", ext, "-y", outputPath)
+
 	cmd := exec.Command("ffmpeg", args...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -480,16 +504,65 @@ func (md *MusicDownloader) addMetadataToFile(filepath string, metadata *TrackMet
 		return fmt.Errorf("could not rename temp file: %v", err)
 	}
 
+	if md.config.EmbedLrc && ext == "mp3" && metadata.SyncedLyrics != "" && !strings.EqualFold(md.config.LrcFormat, "ttml") {
+		if lines, err := parseLRC(metadata.SyncedLyrics); err == nil {
+			if err := embedSyltFrame(filepath, lines); err != nil {
+				color.Yellow("⚠️  Could not embed synced lyrics for %s: %v", metadata.Title, err)
+			}
+		}
+	}
+
 	return nil
 }
 
-func (md *MusicDownloader) ProcessTracks() error {
+// downloadWithRetry drives one queued record through downloadTrack,
+// retrying failures with exponential backoff up to config.MaxRetries before
+// giving up. Once a download resolves an ISRC, it's checked against the
+// store: if another record with that ISRC is already done, the new file is
+// removed and this record points at the existing one. The check runs after
+// the download, so the duplicate is still fetched once.
+func (md *MusicDownloader) downloadWithRetry(store *DownloadStore, record *DownloadRecord, track Track, report ProgressFunc) error {
+	for attempt := 0; ; attempt++ {
+		outputPath, isrc, err := md.downloadTrack(track, report)
+		if err == nil {
+			if done, existingPath, dupErr := store.IsDoneByISRC(isrc); dupErr == nil && done && existingPath != outputPath {
+				os.Remove(outputPath)
+				outputPath = existingPath
+			}
+			return store.MarkDone(record.ID, outputPath, isrc, time.Now())
+		}
+
+		attempts, markErr := store.MarkFailed(record.ID, err, time.Now())
+		if markErr != nil {
+			return markErr
+		}
+		if attempts >= md.config.MaxRetries {
+			return err
+		}
+
+		backoff := time.Duration(md.config.RetryBackoffSeconds) * time.Second * (1 << attempt)
+		emitLog("⚠️  Retrying '%s' in %s (attempt %d/%d): %v", track.Name, backoff, attempts+1, md.config.MaxRetries, err)
+		time.Sleep(backoff)
+	}
+}
+
+// ProcessTracks parses the configured bookmark source, enqueues every track
+// into the download store, and works the queue with config.MaxConcurrent
+// workers. Unless plain is set (or stdout isn't a terminal), progress is
+// rendered as a live bubbletea dashboard; otherwise it falls back to the
+// original progressbar-based output.
+func (md *MusicDownloader) ProcessTracks(plain bool) error {
 	if err := md.setupDependencies(); err != nil {
 		return err
 	}
 
 	color.Cyan("📚 Parsing bookmarks...")
-	tracks, err := md.parseBookmarks()
+	source, err := newBookmarkSource(md.config.SourceType)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := source.ParseTracks(md)
 	if err != nil {
 		return err
 	}
@@ -499,9 +572,39 @@ func (md *MusicDownloader) ProcessTracks() error {
 		return nil
 	}
 
-	color.Green("🎵 Found %d tracks to download", len(tracks))
+	store, err := OpenDownloadStore(md.config.DownloadDBPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
 
-	mainBar := progressbar.NewOptions(len(tracks),
+	if stuck, err := store.RequeueStuck(time.Now()); err != nil {
+		return fmt.Errorf("failed to requeue stuck downloads: %v", err)
+	} else if stuck > 0 {
+		color.Yellow("⚠️  Requeued %d download(s) left in progress by a previous run", stuck)
+	}
+
+	tracksByURL := make(map[string]Track, len(tracks))
+	now := time.Now()
+	for _, track := range tracks {
+		tracksByURL[track.URL] = track
+		if err := store.Enqueue(track, now); err != nil {
+			return fmt.Errorf("failed to enqueue '%s': %v", track.Name, err)
+		}
+	}
+
+	color.Green("🎵 Found %d tracks to process", len(tracks))
+
+	if useTUI(plain) {
+		return md.processTracksWithDashboard(store, tracksByURL, len(tracks))
+	}
+	return md.processTracksPlain(store, tracksByURL, len(tracks))
+}
+
+// processTracksPlain is the original progressbar-based path, used for
+// --plain runs and non-interactive (e.g. CI) stdout.
+func (md *MusicDownloader) processTracksPlain(store *DownloadStore, tracksByURL map[string]Track, total int) error {
+	mainBar := progressbar.NewOptions(total,
 		progressbar.OptionSetDescription("📥 Overall Progress"),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetTheme(progressbar.Theme{
@@ -513,35 +616,162 @@ func (md *MusicDownloader) ProcessTracks() error {
 		}),
 	)
 
-	semaphore := make(chan struct{}, md.config.MaxConcurrent)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
-	for i, track := range tracks {
+	for i := 0; i < md.config.MaxConcurrent; i++ {
 		wg.Add(1)
-		go func(track Track, index int) {
+		go func(worker int) {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			trackBar := progressbar.NewOptions(1,
-				progressbar.OptionSetDescription(fmt.Sprintf("Track %d/%d", index+1, len(tracks))),
-			)
-
-			if err := md.downloadTrack(track, trackBar); err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to download '%s': %v", track.Name, err))
-				mu.Unlock()
-				color.Red("❌ Failed: %s", track.Name)
-			} else {
-				color.Green("✅ Completed: %s", track.Name)
+			for {
+				record, err := store.ClaimNext(time.Now())
+				if err != nil {
+					mu.Lock()
+					errors = append(errors, fmt.Errorf("failed to claim queued track: %v", err))
+					mu.Unlock()
+					return
+				}
+				if record == nil {
+					return
+				}
+
+				track, ok := tracksByURL[record.URL]
+				if !ok {
+					if _, err := store.MarkFailed(record.ID, fmt.Errorf("track no longer present in bookmark source"), time.Now()); err != nil {
+						mu.Lock()
+						errors = append(errors, fmt.Errorf("failed to mark orphaned record %d as failed: %v", record.ID, err))
+						mu.Unlock()
+					}
+					continue
+				}
+
+				trackBar := progressbar.NewOptions(100,
+					progressbar.OptionSetDescription(fmt.Sprintf("Worker %d: %s", worker, track.Name)),
+				)
+				report := func(phase string, percent float64) {
+					trackBar.Describe(fmt.Sprintf("Worker %d: %s (%s)", worker, track.Name, phase))
+					trackBar.Set(int(percent))
+				}
+
+				if err := md.downloadWithRetry(store, record, track, report); err != nil {
+					mu.Lock()
+					errors = append(errors, fmt.Errorf("failed to download '%s': %v", track.Name, err))
+					mu.Unlock()
+					color.Red("❌ Failed: %s", track.Name)
+				} else {
+					color.Green("✅ Completed: %s", track.Name)
+				}
+
+				mainBar.Add(1)
 			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if len(errors) > 0 {
+		color.Red("\n❌ Some downloads failed:")
+		for _, err := range errors {
+			color.Red("  • %v", err)
+		}
+	}
+
+	color.Green("\n🎉 Processing complete! Downloaded to: %s", md.config.MusicDirectory)
+
+	return nil
+}
+
+// processTracksWithDashboard runs the same worker pool as processTracksPlain
+// but renders a bubbletea table (one row per worker) plus a counters/eta
+// footer and log pane, fed by workerUpdateMsg/logMsg/countersMsg sent to
+// uiProgram from the worker goroutines below.
+func (md *MusicDownloader) processTracksWithDashboard(store *DownloadStore, tracksByURL map[string]Track, total int) error {
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	requestStop := func() { stopOnce.Do(func() { close(stopCh) }) }
+
+	model := newDashboardModel(md.config.MaxConcurrent, total, requestStop)
+	uiProgram = tea.NewProgram(model)
+	defer func() { uiProgram = nil }()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var completed, failed int
+	errors := make([]error, 0)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer uiProgram.Send(doneMsg{})
+
+		var innerWg sync.WaitGroup
+		for i := 0; i < md.config.MaxConcurrent; i++ {
+			innerWg.Add(1)
+			go func(worker int) {
+				defer innerWg.Done()
+				for {
+					select {
+					case <-stopCh:
+						return
+					default:
+					}
+
+					record, err := store.ClaimNext(time.Now())
+					if err != nil {
+						mu.Lock()
+						errors = append(errors, fmt.Errorf("failed to claim queued track: %v", err))
+						mu.Unlock()
+						return
+					}
+					if record == nil {
+						return
+					}
+
+					track, ok := tracksByURL[record.URL]
+					if !ok {
+						if _, err := store.MarkFailed(record.ID, fmt.Errorf("track no longer present in bookmark source"), time.Now()); err != nil {
+							mu.Lock()
+							errors = append(errors, fmt.Errorf("failed to mark orphaned record %d as failed: %v", record.ID, err))
+							mu.Unlock()
+						}
+						continue
+					}
+
+					report := func(phase string, percent float64) {
+						uiProgram.Send(workerUpdateMsg{worker: worker, state: workerState{Track: track.Name, Phase: phase, Percent: percent}})
+					}
+
+					err = md.downloadWithRetry(store, record, track, report)
+
+					mu.Lock()
+					if err != nil {
+						errors = append(errors, fmt.Errorf("failed to download '%s': %v", track.Name, err))
+						failed++
+						uiProgram.Send(logMsg(fmt.Sprintf("❌ Failed: %s", track.Name)))
+					} else {
+						completed++
+						uiProgram.Send(logMsg(fmt.Sprintf("✅ Completed: %s", track.Name)))
+					}
+					uiProgram.Send(countersMsg{completed: completed, failed: failed, total: total})
+					mu.Unlock()
+
+					uiProgram.Send(workerUpdateMsg{worker: worker, state: workerState{}})
+				}
+			}(i)
+		}
+		innerWg.Wait()
+	}()
 
-			mainBar.Add(1)
-		}(track, i)
+	if _, err := uiProgram.Run(); err != nil {
+		return fmt.Errorf("dashboard failed: %v", err)
 	}
 
+	select {
+	case <-stopCh:
+		color.Cyan("⏳ Quit requested, finishing in-flight downloads before exiting...")
+	default:
+	}
 	wg.Wait()
 
 	if len(errors) > 0 {
@@ -566,6 +796,30 @@ func loadConfig() Config {
 	return config
 }
 
+// needsSetup reports whether config is missing the fields required to run
+// the bookmark source it's configured for, so the interactive wizard only
+// fires when it would actually be able to fix something.
+func needsSetup(config Config) bool {
+	switch strings.ToLower(config.SourceType) {
+	case "", "chrome":
+		return config.BookmarkPath == ""
+	case "firefox":
+		return config.FirefoxProfilePath == ""
+	case "safari":
+		return config.SafariBookmarksPath == ""
+	case "textfile":
+		return config.TextListPath == ""
+	case "m3u", "m3u8":
+		return config.M3UPath == ""
+	case "obsidian":
+		return config.ObsidianFilePath == ""
+	case "spotify":
+		return config.SpotifyClientID == "" || config.SpotifyClientSecret == ""
+	default:
+		return true
+	}
+}
+
 func saveConfig(config Config) error {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
@@ -594,17 +848,62 @@ func configureInteractively() Config {
 		config.SpotifyClientSecret = input
 	}
 
-	fmt.Printf("Chrome Bookmark Path [%s]: ", config.BookmarkPath)
+	fmt.Printf("Source Type [chrome/firefox/safari/textfile/m3u/obsidian/spotify] [%s]: ", config.SourceType)
 	scanner.Scan()
 	if input := strings.TrimSpace(scanner.Text()); input != "" {
-		config.BookmarkPath = input
+		config.SourceType = input
 	}
 
-	fmt.Printf("Bookmark Folder Position [%d]: ", config.BookmarkPosition)
-	scanner.Scan()
-	if input := strings.TrimSpace(scanner.Text()); input != "" {
-		if pos, err := strconv.Atoi(input); err == nil {
-			config.BookmarkPosition = pos
+	switch strings.ToLower(config.SourceType) {
+	case "firefox":
+		fmt.Printf("Firefox Profile Path [%s]: ", config.FirefoxProfilePath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.FirefoxProfilePath = input
+		}
+	case "safari":
+		fmt.Printf("Safari Bookmarks Path [%s]: ", config.SafariBookmarksPath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.SafariBookmarksPath = input
+		}
+	case "textfile":
+		fmt.Printf("Text List Path [%s]: ", config.TextListPath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.TextListPath = input
+		}
+	case "m3u", "m3u8":
+		fmt.Printf("M3U Path [%s]: ", config.M3UPath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.M3UPath = input
+		}
+	case "obsidian":
+		fmt.Printf("Obsidian File Path [%s]: ", config.ObsidianFilePath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.ObsidianFilePath = input
+		}
+	case "spotify":
+		fmt.Printf("Spotify Playlist ID [%s]: ", config.SpotifyPlaylistID)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.SpotifyPlaylistID = input
+		}
+	default:
+		fmt.Printf("Chrome Bookmark Path [%s]: ", config.BookmarkPath)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			config.BookmarkPath = input
+		}
+
+		fmt.Printf("Bookmark Folder Position [%d]: ", config.BookmarkPosition)
+		scanner.Scan()
+		if input := strings.TrimSpace(scanner.Text()); input != "" {
+			if pos, err := strconv.Atoi(input); err == nil {
+				config.BookmarkPosition = pos
+			}
 		}
 	}
 
@@ -661,25 +960,54 @@ func main() {
 				color.Red("❌ Update failed: %v", err)
 			}
 			return
+		case "queue":
+			if err := runQueueCommand(loadConfig()); err != nil {
+				color.Red("❌ %v", err)
+				os.Exit(1)
+			}
+			return
+		case "retry-failed":
+			if err := runRetryFailedCommand(loadConfig()); err != nil {
+				color.Red("❌ %v", err)
+				os.Exit(1)
+			}
+			return
+		case "status":
+			if err := runStatusCommand(loadConfig()); err != nil {
+				color.Red("❌ %v", err)
+				os.Exit(1)
+			}
+			return
 		case "help", "-h", "--help":
 			fmt.Println("Usage:")
-			fmt.Println("  music-downloader          - Start downloading")
-			fmt.Println("  music-downloader config   - Configure settings")
-			fmt.Println("  music-downloader update   - Update yt-dlp")
-			fmt.Println("  music-downloader help     - Show this help")
+			fmt.Println("  music-downloader              - Start downloading")
+			fmt.Println("  music-downloader --plain       - Start downloading without the TUI dashboard")
+			fmt.Println("  music-downloader config       - Configure settings")
+			fmt.Println("  music-downloader update       - Update yt-dlp")
+			fmt.Println("  music-downloader queue        - List queued/in-progress/failed downloads")
+			fmt.Println("  music-downloader retry-failed - Requeue failed downloads")
+			fmt.Println("  music-downloader status       - Show download queue counts")
+			fmt.Println("  music-downloader help         - Show this help")
 			return
 		}
 	}
 
+	plain := false
+	for _, arg := range os.Args[1:] {
+		if arg == "--plain" {
+			plain = true
+		}
+	}
+
 	config := loadConfig()
 
-	if config.SpotifyClientID == "" || config.BookmarkPath == "" {
+	if needsSetup(config) {
 		color.Yellow("⚠️  Configuration needed. Running setup...")
 		config = configureInteractively()
 	}
 
 	md := NewMusicDownloader(config)
-	if err := md.ProcessTracks(); err != nil {
+	if err := md.ProcessTracks(plain); err != nil {
 		color.Red("❌ Error: %v", err)
 		os.Exit(1)
 	}
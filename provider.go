@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProgressFunc reports a provider's progress as it works through a track,
+// e.g. ("downloading", 42.5). Callers may pass nil when they don't care.
+type ProgressFunc func(phase string, percent float64)
+
+// Provider resolves and downloads a single Track's audio to outputPath
+// (without the file extension yt-dlp/ffmpeg will append). This is the
+// extension point for adding sources beyond yt-dlp, e.g. the Apple Music
+// provider.
+type Provider interface {
+	Name() string
+	CanHandle(track Track) bool
+	// Extension is the file extension (without dot) this provider writes,
+	// e.g. "mp3" or, for lossless sources, "m4a"/"flac".
+	Extension(md *MusicDownloader) string
+	// HandlesOwnMetadata reports whether Download already embeds tags and
+	// cover art, so downloadTrack should skip the Spotify metadata pass.
+	HandlesOwnMetadata() bool
+	Download(md *MusicDownloader, track Track, outputPath string, onProgress ProgressFunc) error
+}
+
+// providers are tried in order; the first one whose CanHandle returns true
+// is used for that track.
+func providers() []Provider {
+	return []Provider{
+		AppleMusicProvider{},
+		YtDlpProvider{},
+	}
+}
+
+func providerFor(track Track) (Provider, error) {
+	for _, p := range providers() {
+		if p.CanHandle(track) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no provider available for %s", track.URL)
+}
+
+// YtDlpProvider shells out to yt-dlp, the original (and still default)
+// download path. It also transparently handles tracks whose URL is a
+// Spotify URI by turning them into a yt-dlp search query, since Spotify
+// itself serves no playable audio stream.
+type YtDlpProvider struct{}
+
+func (YtDlpProvider) Name() string { return "yt-dlp" }
+
+func (YtDlpProvider) CanHandle(track Track) bool { return true }
+
+func (YtDlpProvider) Extension(md *MusicDownloader) string { return "mp3" }
+
+func (YtDlpProvider) HandlesOwnMetadata() bool { return false }
+
+// ytDlpProgressPattern matches yt-dlp's own progress template output:
+// "<percent>|<downloaded>|<speed>", e.g. "42.5%|3.1MiB|1.2MiB/s".
+var ytDlpProgressPattern = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)%`)
+
+func (YtDlpProvider) Download(md *MusicDownloader, track Track, outputPath string, onProgress ProgressFunc) error {
+	source := track.URL
+	if strings.HasPrefix(source, "spotify:track:") {
+		source = fmt.Sprintf("ytsearch1:%s %s", track.Title, track.Artist)
+	}
+
+	cmd := exec.Command(md.config.YtDlpPath,
+		"--extract-audio",
+		"--audio-format", "mp3",
+		"--audio-quality", md.config.AudioQuality,
+		"--output", outputPath+".%(ext)s",
+		"--newline",
+		"--progress-template", "%(progress._percent_str)s|%(progress._downloaded_bytes_str)s|%(progress._speed_str)s",
+		"--no-warnings",
+		source,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if onProgress == nil {
+			continue
+		}
+		if match := ytDlpProgressPattern.FindStringSubmatch(scanner.Text()); match != nil {
+			if percent, err := strconv.ParseFloat(match[1], 64); err == nil {
+				onProgress("downloading", percent)
+			}
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("download failed: %v - %s", err, stderr.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	spotifyAuthorizeURL = "https://accounts.spotify.com/authorize"
+	spotifyTokenURL     = "https://accounts.spotify.com/api/token"
+	spotifyAuthScopes   = "user-library-read playlist-read-private playlist-read-collaborative"
+)
+
+// SpotifyPKCETokens is persisted to disk so the user isn't prompted to log
+// in again on every run.
+type SpotifyPKCETokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (t SpotifyPKCETokens) expired() bool {
+	return time.Now().After(t.ExpiresAt.Add(-30 * time.Second))
+}
+
+func loadSpotifyPKCETokens(path string) (*SpotifyPKCETokens, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tokens SpotifyPKCETokens
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+func saveSpotifyPKCETokens(path string, tokens SpotifyPKCETokens) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// generateCodeVerifier returns a random 43-128 char unreserved-character
+// string per RFC 7636.
+func generateCodeVerifier() (string, error) {
+	buf := make([]byte, 64)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorizePKCE runs the Authorization Code with PKCE flow: it opens the
+// user's browser to Spotify's consent screen and listens on a loopback
+// server for the redirect carrying the authorization code.
+func authorizePKCE(clientID, redirectPort string) (*SpotifyPKCETokens, error) {
+	verifier, err := generateCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %v", err)
+	}
+	challenge := codeChallengeS256(verifier)
+
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%s/callback", redirectPort)
+	authURL := fmt.Sprintf("%s?response_type=code&client_id=%s&scope=%s&redirect_uri=%s&code_challenge_method=S256&code_challenge=%s",
+		spotifyAuthorizeURL, clientID, strings.ReplaceAll(spotifyAuthScopes, " ", "%20"), redirectURI, challenge)
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+			errCh <- fmt.Errorf("spotify authorization denied: %s", errMsg)
+			fmt.Fprintln(w, "Authorization failed, you can close this tab.")
+			return
+		}
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			errCh <- fmt.Errorf("no authorization code in callback")
+			return
+		}
+		codeCh <- code
+		fmt.Fprintln(w, "Authorization complete, you can close this tab.")
+	})
+
+	server := &http.Server{Addr: "127.0.0.1:" + redirectPort, Handler: mux}
+	go server.ListenAndServe()
+	defer server.Shutdown(context.Background())
+
+	color.Cyan("🔑 Opening browser for Spotify login...")
+	openBrowser(authURL)
+
+	select {
+	case code := <-codeCh:
+		return exchangeAuthorizationCode(clientID, code, redirectURI, verifier)
+	case err := <-errCh:
+		return nil, err
+	case <-time.After(3 * time.Minute):
+		return nil, fmt.Errorf("timed out waiting for Spotify authorization")
+	}
+}
+
+func exchangeAuthorizationCode(clientID, code, redirectURI, verifier string) (*SpotifyPKCETokens, error) {
+	form := fmt.Sprintf("grant_type=authorization_code&code=%s&redirect_uri=%s&client_id=%s&code_verifier=%s",
+		code, redirectURI, clientID, verifier)
+	return requestSpotifyPKCEToken(form)
+}
+
+func refreshPKCEToken(clientID, refreshToken string) (*SpotifyPKCETokens, error) {
+	form := fmt.Sprintf("grant_type=refresh_token&refresh_token=%s&client_id=%s", refreshToken, clientID)
+	return requestSpotifyPKCEToken(form)
+}
+
+func requestSpotifyPKCEToken(form string) (*SpotifyPKCETokens, error) {
+	req, err := http.NewRequest("POST", spotifyTokenURL, strings.NewReader(form))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, err
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("spotify token exchange failed")
+	}
+
+	return &SpotifyPKCETokens{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// ensureSpotifyPKCEToken loads persisted tokens, refreshing or running the
+// full browser flow as needed, and returns a valid access token.
+func ensureSpotifyPKCEToken(clientID, tokenPath, redirectPort string) (string, error) {
+	tokens, err := loadSpotifyPKCETokens(tokenPath)
+	if err != nil {
+		tokens, err = authorizePKCE(clientID, redirectPort)
+		if err != nil {
+			return "", err
+		}
+		if err := saveSpotifyPKCETokens(tokenPath, *tokens); err != nil {
+			return "", err
+		}
+		return tokens.AccessToken, nil
+	}
+
+	if tokens.expired() {
+		refreshed, err := refreshPKCEToken(clientID, tokens.RefreshToken)
+		if err != nil {
+			refreshed, err = authorizePKCE(clientID, redirectPort)
+			if err != nil {
+				return "", err
+			}
+		}
+		if refreshed.RefreshToken == "" {
+			refreshed.RefreshToken = tokens.RefreshToken
+		}
+		if err := saveSpotifyPKCETokens(tokenPath, *refreshed); err != nil {
+			return "", err
+		}
+		return refreshed.AccessToken, nil
+	}
+
+	return tokens.AccessToken, nil
+}
+
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	cmd.Start()
+}
+
+// SpotifyLibraryBookmarkSource pulls tracks from the user's Spotify liked
+// songs or a playlist (configured via SpotifyPlaylistID) instead of a local
+// bookmark file, authenticating with the PKCE flow above.
+type SpotifyLibraryBookmarkSource struct{}
+
+type spotifyPagedTracksResponse struct {
+	Items []struct {
+		Track struct {
+			ID      string `json:"id"`
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		} `json:"track"`
+	} `json:"items"`
+	Next string `json:"next"`
+}
+
+func (SpotifyLibraryBookmarkSource) ParseTracks(md *MusicDownloader) ([]Track, error) {
+	if md.config.SpotifyClientID == "" {
+		return nil, fmt.Errorf("spotify_client_id not configured")
+	}
+
+	redirectPort := md.config.SpotifyRedirectPort
+	if redirectPort == "" {
+		redirectPort = "8888"
+	}
+	tokenPath := md.config.SpotifyTokenPath
+	if tokenPath == "" {
+		tokenPath = "spotify_tokens.json"
+	}
+
+	accessToken, err := ensureSpotifyPKCEToken(md.config.SpotifyClientID, tokenPath, redirectPort)
+	if err != nil {
+		return nil, fmt.Errorf("spotify authentication failed: %v", err)
+	}
+
+	endpoint := "https://api.spotify.com/v1/me/tracks?limit=50"
+	if md.config.SpotifyPlaylistID != "" {
+		endpoint = fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?limit=100", md.config.SpotifyPlaylistID)
+	}
+
+	var tracks []Track
+	for endpoint != "" {
+		req, err := http.NewRequest("GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+
+		resp, err := md.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page spotifyPagedTracksResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+
+		for _, item := range page.Items {
+			if item.Track.ID == "" || len(item.Track.Artists) == 0 {
+				continue
+			}
+			tracks = append(tracks, Track{
+				URL:    "spotify:track:" + item.Track.ID,
+				Name:   fmt.Sprintf("%s%s%s", item.Track.Name, md.config.MusicSeparator, item.Track.Artists[0].Name),
+				Title:  item.Track.Name,
+				Artist: item.Track.Artists[0].Name,
+			})
+		}
+
+		endpoint = page.Next
+	}
+
+	return tracks, nil
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DownloadStatus is the lifecycle state of a queued download.
+type DownloadStatus string
+
+const (
+	StatusQueued      DownloadStatus = "queued"
+	StatusDownloading DownloadStatus = "downloading"
+	StatusDone        DownloadStatus = "done"
+	StatusFailed      DownloadStatus = "failed"
+)
+
+// DownloadRecord is one row of the download_queue table.
+type DownloadRecord struct {
+	ID        int64
+	URL       string
+	TrackID   string
+	ISRC      string
+	FilePath  string
+	Checksum  string
+	Status    DownloadStatus
+	Attempts  int
+	LastError string
+}
+
+// DownloadStore is a SQLite-backed queue that lets ProcessTracks resume a
+// large library cleanly after a crash instead of re-scanning the music
+// directory on every run.
+type DownloadStore struct {
+	db *sql.DB
+}
+
+func OpenDownloadStore(path string) (*DownloadStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download store: %v", err)
+	}
+	// SQLite only allows one writer at a time; capping the pool to a single
+	// connection avoids SQLITE_BUSY errors from concurrent workers even with
+	// the busy timeout above.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS download_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			track_id TEXT,
+			isrc TEXT,
+			file_path TEXT,
+			checksum TEXT,
+			status TEXT NOT NULL DEFAULT 'queued',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create download_queue table: %v", err)
+	}
+
+	return &DownloadStore{db: db}, nil
+}
+
+func (s *DownloadStore) Close() error {
+	return s.db.Close()
+}
+
+// Enqueue inserts a queued row for track unless one already exists for its
+// URL, in which case it is left untouched so a resumed run doesn't reset
+// progress on already-downloaded or in-flight tracks.
+func (s *DownloadStore) Enqueue(track Track, now time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO download_queue (url, name, status, attempts, created_at, updated_at)
+		VALUES (?, ?, ?, 0, ?, ?)
+		ON CONFLICT(url) DO NOTHING
+	`, track.URL, track.Name, StatusQueued, now, now)
+	return err
+}
+
+// ClaimNext atomically marks the oldest queued row as downloading and
+// returns it, so concurrent workers never race for the same track.
+func (s *DownloadStore) ClaimNext(now time.Time) (*DownloadRecord, error) {
+	row := s.db.QueryRow(`
+		UPDATE download_queue
+		SET status = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM download_queue WHERE status = ? ORDER BY id LIMIT 1
+		)
+		RETURNING id, url, name, attempts
+	`, StatusDownloading, now, StatusQueued)
+
+	var rec DownloadRecord
+	var name string
+	if err := row.Scan(&rec.ID, &rec.URL, &name, &rec.Attempts); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rec.TrackID = name
+	rec.Status = StatusDownloading
+
+	return &rec, nil
+}
+
+// MarkDone records a successful download, its final file path, and a SHA256
+// checksum of that file (empty if the file can't be read). Nothing in this
+// package reads the checksum back.
+func (s *DownloadStore) MarkDone(id int64, filePath, isrc string, now time.Time) error {
+	checksum, err := fileChecksum(filePath)
+	if err != nil {
+		checksum = ""
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE download_queue
+		SET status = ?, file_path = ?, checksum = ?, isrc = ?, updated_at = ?
+		WHERE id = ?
+	`, StatusDone, filePath, checksum, isrc, now, id)
+
+	return err
+}
+
+// MarkFailed increments the attempt counter and records the error. Callers
+// decide, based on the returned attempt count, whether to retry with
+// backoff or give up.
+func (s *DownloadStore) MarkFailed(id int64, cause error, now time.Time) (attempts int, err error) {
+	_, err = s.db.Exec(`
+		UPDATE download_queue
+		SET status = ?, attempts = attempts + 1, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`, StatusFailed, cause.Error(), now, id)
+	if err != nil {
+		return 0, err
+	}
+
+	row := s.db.QueryRow(`SELECT attempts FROM download_queue WHERE id = ?`, id)
+	if err := row.Scan(&attempts); err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// IsDoneByISRC reports whether a track with this ISRC has already been
+// downloaded successfully and, if so, its file path, so a second copy of the
+// same song reached via a different URL can be discarded after download.
+func (s *DownloadStore) IsDoneByISRC(isrc string) (bool, string, error) {
+	if isrc == "" {
+		return false, "", nil
+	}
+
+	row := s.db.QueryRow(`SELECT file_path FROM download_queue WHERE isrc = ? AND status = ? LIMIT 1`, isrc, StatusDone)
+	var filePath string
+	if err := row.Scan(&filePath); err != nil {
+		if err == sql.ErrNoRows {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	return true, filePath, nil
+}
+
+// RequeueStuck resets every row left in 'downloading' back to queued, for
+// callers to run once at startup. A row stays 'downloading' only if the
+// process was killed mid-claim; ClaimNext never otherwise revisits it, so
+// without this pass such rows would be wedged forever.
+func (s *DownloadStore) RequeueStuck(now time.Time) (int, error) {
+	result, err := s.db.Exec(`
+		UPDATE download_queue
+		SET status = ?, updated_at = ?
+		WHERE status = ?
+	`, StatusQueued, now, StatusDownloading)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// RequeueFailed resets every failed row with fewer than maxAttempts tries
+// back to queued, returning how many were requeued.
+func (s *DownloadStore) RequeueFailed(maxAttempts int, now time.Time) (int, error) {
+	result, err := s.db.Exec(`
+		UPDATE download_queue
+		SET status = ?, updated_at = ?
+		WHERE status = ? AND attempts < ?
+	`, StatusQueued, now, StatusFailed, maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// StatusCounts returns how many rows are in each status, for the `status`
+// CLI command.
+func (s *DownloadStore) StatusCounts() (map[DownloadStatus]int, error) {
+	rows, err := s.db.Query(`SELECT status, COUNT(*) FROM download_queue GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[DownloadStatus]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[DownloadStatus(status)] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// ListByStatus returns every row with the given status, ordered by id, for
+// the `queue` CLI command.
+func (s *DownloadStore) ListByStatus(status DownloadStatus) ([]DownloadRecord, error) {
+	rows, err := s.db.Query(`SELECT id, url, name, attempts, last_error FROM download_queue WHERE status = ? ORDER BY id`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DownloadRecord
+	for rows.Next() {
+		var rec DownloadRecord
+		var lastError sql.NullString
+		if err := rows.Scan(&rec.ID, &rec.URL, &rec.TrackID, &rec.Attempts, &lastError); err != nil {
+			return nil, err
+		}
+		rec.Status = status
+		rec.LastError = lastError.String
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
+
+// runQueueCommand prints every queued, downloading and failed row, for the `queue` CLI
+// command.
+func runQueueCommand(config Config) error {
+	store, err := OpenDownloadStore(config.DownloadDBPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, status := range []DownloadStatus{StatusQueued, StatusDownloading, StatusFailed} {
+		records, err := store.ListByStatus(status)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s (%d):\n", status, len(records))
+		for _, rec := range records {
+			if rec.LastError != "" {
+				fmt.Printf("  [%d] %s (attempts: %d, last error: %s)\n", rec.ID, rec.TrackID, rec.Attempts, rec.LastError)
+			} else {
+				fmt.Printf("  [%d] %s\n", rec.ID, rec.TrackID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runRetryFailedCommand requeues every failed row under config.MaxRetries,
+// for the `retry-failed` CLI command.
+func runRetryFailedCommand(config Config) error {
+	store, err := OpenDownloadStore(config.DownloadDBPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	requeued, err := store.RequeueFailed(config.MaxRetries, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Requeued %d failed download(s)\n", requeued)
+
+	return nil
+}
+
+// runStatusCommand prints a count of rows per status, for the `status` CLI
+// command.
+func runStatusCommand(config Config) error {
+	store, err := OpenDownloadStore(config.DownloadDBPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	counts, err := store.StatusCounts()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range []DownloadStatus{StatusQueued, StatusDownloading, StatusDone, StatusFailed} {
+		fmt.Printf("%-12s %d\n", status, counts[status])
+	}
+
+	return nil
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
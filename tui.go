@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// uiProgram is set while the bubbletea dashboard is running, so emitLog and
+// worker progress callbacks know to route through it instead of printing
+// straight to stdout (see ProcessTracks).
+var uiProgram *tea.Program
+
+// emitLog surfaces a log line either into the TUI's scrollable log pane or,
+// when the TUI isn't active, straight to stdout.
+func emitLog(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	if uiProgram != nil {
+		uiProgram.Send(logMsg(line))
+		return
+	}
+	fmt.Println(line)
+}
+
+// useTUI decides whether to render the bubbletea dashboard. It's the
+// default; --plain (or a non-interactive stdout, e.g. CI) falls back to the
+// original progressbar-based output.
+func useTUI(plainFlag bool) bool {
+	if plainFlag {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// workerState is the latest known status of one download worker, rendered
+// as a single row of the dashboard's table.
+type workerState struct {
+	Track   string
+	Phase   string
+	Percent float64
+}
+
+type workerUpdateMsg struct {
+	worker int
+	state  workerState
+}
+
+type logMsg string
+
+type countersMsg struct {
+	completed int
+	failed    int
+	total     int
+}
+
+type doneMsg struct{}
+
+// dashboardModel is the bubbletea model backing ProcessTracks' live view:
+// a fixed-height table of active workers, a counters/eta footer, and a log
+// pane fed by emitLog. The pane shows only the latest logPaneHeight lines;
+// it has no scrolling.
+type dashboardModel struct {
+	workers     []workerState
+	logs        []string
+	completed   int
+	failed      int
+	total       int
+	start       time.Time
+	requestStop func()
+}
+
+func newDashboardModel(workerCount, total int, requestStop func()) dashboardModel {
+	return dashboardModel{
+		workers:     make([]workerState, workerCount),
+		total:       total,
+		start:       time.Now(),
+		requestStop: requestStop,
+	}
+}
+
+func (m dashboardModel) Init() tea.Cmd { return nil }
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "q" {
+			if m.requestStop != nil {
+				m.requestStop()
+			}
+			return m, tea.Quit
+		}
+	case workerUpdateMsg:
+		if msg.worker >= 0 && msg.worker < len(m.workers) {
+			m.workers[msg.worker] = msg.state
+		}
+	case logMsg:
+		const maxLogLines = 200
+		m.logs = append(m.logs, string(msg))
+		if len(m.logs) > maxLogLines {
+			m.logs = m.logs[len(m.logs)-maxLogLines:]
+		}
+	case countersMsg:
+		m.completed, m.failed, m.total = msg.completed, msg.failed, msg.total
+	case doneMsg:
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m dashboardModel) eta() string {
+	done := m.completed + m.failed
+	if done == 0 || done >= m.total {
+		return "--"
+	}
+	elapsed := time.Since(m.start)
+	remaining := time.Duration(float64(elapsed) / float64(done) * float64(m.total-done))
+	return remaining.Round(time.Second).String()
+}
+
+const logPaneHeight = 8
+
+func (m dashboardModel) View() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "🎵 Music Downloader — %d/%d done, %d failed, eta %s\n\n",
+		m.completed+m.failed, m.total, m.failed, m.eta())
+
+	fmt.Fprintf(&b, "%-3s %-40s %-16s %6s\n", "#", "TRACK", "PHASE", "%")
+	for i, w := range m.workers {
+		track := w.Track
+		if track == "" {
+			track = "(idle)"
+		}
+		if len(track) > 40 {
+			track = track[:37] + "..."
+		}
+		fmt.Fprintf(&b, "%-3d %-40s %-16s %5.1f%%\n", i, track, w.Phase, w.Percent)
+	}
+
+	b.WriteString("\n── log ──────────────────────────────────────────────\n")
+	start := 0
+	if len(m.logs) > logPaneHeight {
+		start = len(m.logs) - logPaneHeight
+	}
+	for _, line := range m.logs[start:] {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("\n(q to quit — stops queuing new downloads; in-flight downloads finish first)\n")
+
+	return b.String()
+}